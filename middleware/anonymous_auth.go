@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// AnonymousUsername and AnonymousGroup mirror the identity Kubernetes'
+// apiserver assigns to a request that presents no credentials.
+const (
+	AnonymousUsername = "system:anonymous"
+	AnonymousGroup    = "system:unauthenticated"
+)
+
+// AnonymousAuthenticator always succeeds, authenticating the request as the
+// anonymous user. It is meant to be the last entry in an
+// AuthenticationMiddleware's Authenticators chain so that requests with no
+// credentials still reach the Authorizer instead of failing authentication
+// outright — the same pattern Kubernetes' apiserver uses to let a
+// path-based authorizer (e.g. PathAuthorizer) expose endpoints like
+// /healthz without requiring every caller to authenticate.
+type AnonymousAuthenticator struct{}
+
+func (AnonymousAuthenticator) AuthenticateRequest(r *http.Request) (authenticationv1.UserInfo, bool, error) {
+	return authenticationv1.UserInfo{Username: AnonymousUsername, Groups: []string{AnonymousGroup}}, true, nil
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnonymousAuthenticator(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/healthz", nil)
+
+	user, ok, err := AnonymousAuthenticator{}.AuthenticateRequest(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected AnonymousAuthenticator to always authenticate")
+	}
+	if user.Username != AnonymousUsername {
+		t.Fatalf("expected username %q, got: %q", AnonymousUsername, user.Username)
+	}
+	if len(user.Groups) != 1 || user.Groups[0] != AnonymousGroup {
+		t.Fatalf("expected groups [%q], got: %v", AnonymousGroup, user.Groups)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// AuditEvent is the structured record written for a single authentication or
+// authorization decision, with enough detail to correlate an OSB request
+// across broker logs, kube-apiserver audit logs, and the invoking
+// service-catalog controller.
+type AuditEvent struct {
+	Timestamp      time.Time     `json:"timestamp"`
+	RequestID      string        `json:"requestID,omitempty"`
+	RequestURI     string        `json:"requestURI"`
+	Verb           string        `json:"verb"`
+	User           string        `json:"user,omitempty"`
+	Groups         []string      `json:"groups,omitempty"`
+	Decision       string        `json:"decision"`
+	Reason         string        `json:"reason,omitempty"`
+	Latency        time.Duration `json:"latency"`
+	TokenReviewUID string        `json:"tokenReviewUID,omitempty"`
+}
+
+// AuditSink records authentication and authorization decisions. Callers can
+// register their own implementation (e.g. to forward events to a SIEM) or
+// use one of the sinks provided in this package.
+type AuditSink interface {
+	RecordAuthn(event AuditEvent)
+	RecordAuthz(event AuditEvent)
+}
+
+// JSONLineAuditSink writes each AuditEvent as a single line of JSON to
+// Writer, suitable for a sidecar to tail or for shipping straight to a log
+// aggregator.
+type JSONLineAuditSink struct {
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+func (s *JSONLineAuditSink) RecordAuthn(event AuditEvent) { s.write("authn", event) }
+func (s *JSONLineAuditSink) RecordAuthz(event AuditEvent) { s.write("authz", event) }
+
+func (s *JSONLineAuditSink) write(kind string, event AuditEvent) {
+	line := struct {
+		Kind string `json:"kind"`
+		AuditEvent
+	}{Kind: kind, AuditEvent: event}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.Writer.Write(data)
+}
+
+// GlogAuditSink records audit events via glog, matching the logging
+// convention already used elsewhere in this library.
+type GlogAuditSink struct{}
+
+func (GlogAuditSink) RecordAuthn(event AuditEvent) {
+	glog.Infof("audit authn request_id=%s user=%s decision=%s reason=%q latency=%s", event.RequestID, event.User, event.Decision, event.Reason, event.Latency)
+}
+
+func (GlogAuditSink) RecordAuthz(event AuditEvent) {
+	glog.Infof("audit authz request_id=%s user=%s verb=%s uri=%s decision=%s reason=%q latency=%s", event.RequestID, event.User, event.Verb, event.RequestURI, event.Decision, event.Reason, event.Latency)
+}
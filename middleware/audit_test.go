@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONLineAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &JSONLineAuditSink{Writer: &buf}
+
+	sink.RecordAuthn(AuditEvent{RequestID: "req-1", User: "foo", Decision: "authenticated", Latency: time.Millisecond})
+	sink.RecordAuthz(AuditEvent{RequestID: "req-1", User: "foo", Decision: "allowed", Latency: time.Millisecond})
+
+	scanner := bufio.NewScanner(&buf)
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid json line: %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit lines, got: %d", len(lines))
+	}
+	if lines[0]["kind"] != "authn" || lines[0]["decision"] != "authenticated" {
+		t.Fatalf("unexpected authn line: %+v", lines[0])
+	}
+	if lines[1]["kind"] != "authz" || lines[1]["decision"] != "allowed" {
+		t.Fatalf("unexpected authz line: %+v", lines[1])
+	}
+}
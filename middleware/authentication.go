@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// tokenReviewUIDExtraKey is the UserInfo.Extra key TokenReviewAuthenticator
+// stashes the originating TokenReview's UID under, matching the convention
+// used by Kubernetes' own webhook token authenticator.
+const tokenReviewUIDExtraKey = "authentication.kubernetes.io/token-review-uid"
+
+// AuthenticationMiddleware authenticates incoming requests using an ordered
+// list of Authenticators and, once authenticated, optionally authorizes the
+// resulting identity before invoking the next handler. This generalizes the
+// original token-only authentication middleware to support any number of
+// pluggable credential types (bearer tokens, client certificates, HTTP
+// Basic auth, ...) tried in turn, à la Kubernetes' own authenticator union.
+type AuthenticationMiddleware struct {
+	// Authenticators are tried in order; the first one to recognize the
+	// request's credentials wins.
+	Authenticators []Authenticator
+
+	// Authorizer, if set, is consulted once the request has been
+	// authenticated. Any Decision other than DecisionAllowed results in a
+	// 401 response. To expose a path without requiring real credentials
+	// (e.g. /healthz), add AnonymousAuthenticator as the last Authenticator
+	// and pair it with a PathAuthorizer that allows that path.
+	Authorizer UserInfoAuthorizer
+
+	// Audit, if set, receives a structured event for every authentication
+	// and authorization decision made while serving a request.
+	Audit AuditSink
+}
+
+func (a AuthenticationMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		user, ok, err := UnionAuthenticator(a.Authenticators).AuthenticateRequest(r)
+		a.recordAuthn(r, user, ok, err, time.Since(start))
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unable to find authentication token")
+			return
+		}
+
+		if a.Authorizer != nil {
+			start = time.Now()
+			decision, err := a.Authorizer.Authorize(user, r)
+			a.recordAuthz(r, user, decision, err, time.Since(start))
+			if err != nil || decision != DecisionAllowed {
+				writeError(w, http.StatusUnauthorized, "unable to authorize user")
+				return
+			}
+		}
+
+		ctx := contextWithUserInfo(r.Context(), user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (a AuthenticationMiddleware) recordAuthn(r *http.Request, user authenticationv1.UserInfo, ok bool, err error, latency time.Duration) {
+	if a.Audit == nil {
+		return
+	}
+
+	decision, reason := "authenticated", ""
+	switch {
+	case err != nil:
+		decision, reason = "error", err.Error()
+	case !ok:
+		decision = "unauthenticated"
+	}
+
+	var tokenReviewUID string
+	if len(user.Extra[tokenReviewUIDExtraKey]) > 0 {
+		tokenReviewUID = user.Extra[tokenReviewUIDExtraKey][0]
+	}
+
+	requestID, _ := RequestIDFromContext(r.Context())
+	a.Audit.RecordAuthn(AuditEvent{
+		Timestamp:      time.Now(),
+		RequestID:      requestID,
+		RequestURI:     r.URL.RequestURI(),
+		Verb:           r.Method,
+		User:           user.Username,
+		Groups:         user.Groups,
+		Decision:       decision,
+		Reason:         reason,
+		Latency:        latency,
+		TokenReviewUID: tokenReviewUID,
+	})
+}
+
+func (a AuthenticationMiddleware) recordAuthz(r *http.Request, user authenticationv1.UserInfo, decision Decision, err error, latency time.Duration) {
+	if a.Audit == nil {
+		return
+	}
+
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+
+	requestID, _ := RequestIDFromContext(r.Context())
+	a.Audit.RecordAuthz(AuditEvent{
+		Timestamp:  time.Now(),
+		RequestID:  requestID,
+		RequestURI: r.URL.RequestURI(),
+		Verb:       r.Method,
+		User:       user.Username,
+		Groups:     user.Groups,
+		Decision:   decisionString(decision),
+		Reason:     reason,
+		Latency:    latency,
+	})
+}
+
+func decisionString(d Decision) string {
+	switch d {
+	case DecisionAllowed:
+		return "allowed"
+	case DecisionDeny:
+		return "denied"
+	default:
+		return "no_opinion"
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAuthenticationMiddleware(t *testing.T) {
+	cases := []struct {
+		name         string
+		url          string
+		authn        []Authenticator
+		authorizer   UserInfoAuthorizer
+		header       string
+		responseCode int
+		errorMessage string
+	}{
+		{
+			name:         "no auth string",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fake.NewSimpleClientset().AuthenticationV1().TokenReviews()}},
+			responseCode: http.StatusUnauthorized,
+			errorMessage: "unable to find authentication token",
+		},
+		{
+			name:         "anonymous authenticator paired with a path authorizer exposes a public path",
+			url:          "/healthz",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fake.NewSimpleClientset().AuthenticationV1().TokenReviews()}, AnonymousAuthenticator{}},
+			authorizer:   PathAuthorizer{Paths: []string{"/healthz", "/metrics"}},
+			responseCode: http.StatusOK,
+		},
+		{
+			name:         "anonymous request to a non-public path is denied",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fake.NewSimpleClientset().AuthenticationV1().TokenReviews()}, AnonymousAuthenticator{}},
+			authorizer:   PathAuthorizer{Paths: []string{"/healthz", "/metrics"}},
+			responseCode: http.StatusUnauthorized,
+			errorMessage: "unable to authorize user",
+		},
+		{
+			name:         "authenticated user",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true}}}}},
+			header:       "bearer newsimpletoken",
+			responseCode: http.StatusOK,
+		},
+		{
+			name:         "authenticated & denied user",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true}}}}},
+			authorizer:   fakeUserInfoAuthorizer{decision: DecisionDeny},
+			header:       "bearer newsimpletoken",
+			responseCode: http.StatusUnauthorized,
+			errorMessage: "unable to authorize user",
+		},
+		{
+			name:         "authenticated & error authorizing user",
+			authn:        []Authenticator{TokenReviewAuthenticator{TokenReview: fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true}}}}},
+			authorizer:   fakeUserInfoAuthorizer{decision: DecisionDeny, err: fmt.Errorf("unable to complete SAR")},
+			header:       "bearer newsimpletoken",
+			responseCode: http.StatusUnauthorized,
+			errorMessage: "unable to authorize user",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			am := AuthenticationMiddleware{
+				Authenticators: tc.authn,
+				Authorizer:     tc.authorizer,
+			}
+
+			url := "http://example.com/foo"
+			if tc.url != "" {
+				url = tc.url
+			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			if tc.header != "" {
+				req.Header.Add("Authorization", tc.header)
+			}
+
+			w := httptest.NewRecorder()
+			am.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.responseCode {
+				t.Fatalf("invalid response code expected %v, got: %v", tc.responseCode, resp.StatusCode)
+			}
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+
+			if resp.Header.Get("Content-Type") != "application/json" {
+				t.Fatalf("invalid content type expected %v, got: %v", "application/json", resp.Header.Get("Content-Type"))
+			}
+			defer resp.Body.Close()
+			e := osbError{}
+			if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+				t.Fatalf("invalid json data in response body: %v", err)
+			}
+			if e.Description != tc.errorMessage {
+				t.Fatalf("invalid error description expected %v, got: %v", tc.errorMessage, e.Description)
+			}
+		})
+	}
+}
+
+// countingAuditSink records how many times each kind of event was recorded,
+// so tests can catch an authorizer double-auditing a single decision.
+type countingAuditSink struct {
+	authnCalls int
+	authzCalls int
+}
+
+func (c *countingAuditSink) RecordAuthn(AuditEvent) { c.authnCalls++ }
+func (c *countingAuditSink) RecordAuthz(AuditEvent) { c.authzCalls++ }
+
+func TestAuthenticationMiddlewareAuditsOncePerDecision(t *testing.T) {
+	sink := &countingAuditSink{}
+	sar := fakeSubjectAccessReview{
+		SubjectAccessReview: &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   "foo",
+				Groups: []string{},
+				Extra:  nil,
+				NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+					Path: "/testing",
+					Verb: http.MethodGet,
+				},
+			},
+			Status: authorizationv1.SubjectAccessReviewStatus{Allowed: true},
+		},
+	}
+
+	am := AuthenticationMiddleware{
+		Authenticators: []Authenticator{TokenReviewAuthenticator{TokenReview: fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "foo", Groups: []string{}}}}}}},
+		Authorizer:     SARUserInfoAuthorizer{SAR: sar},
+		Audit:          sink,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/testing", nil)
+	req.Header.Add("Authorization", "bearer newsimpletoken")
+	w := httptest.NewRecorder()
+	am.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, req)
+
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("expected request to be allowed, got status: %v", w.Result().StatusCode)
+	}
+	if sink.authnCalls != 1 {
+		t.Fatalf("expected exactly 1 authn audit event, got: %d", sink.authnCalls)
+	}
+	if sink.authzCalls != 1 {
+		t.Fatalf("expected exactly 1 authz audit event, got: %d", sink.authzCalls)
+	}
+}
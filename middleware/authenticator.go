@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// Authenticator authenticates an incoming HTTP request, returning the
+// identity of the caller. ok is false when the Authenticator has no opinion
+// on the request (e.g. it found no credential it understands), in which
+// case callers should try the next Authenticator in line.
+type Authenticator interface {
+	AuthenticateRequest(r *http.Request) (user authenticationv1.UserInfo, ok bool, err error)
+}
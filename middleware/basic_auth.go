@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+	"golang.org/x/crypto/bcrypt"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// errInvalidUsernameOrPassword is returned for both an unknown username and
+// a wrong password, so a caller can't use the response to enumerate valid
+// usernames. The underlying cause (missing Secret, Kubernetes API error,
+// bad password) is logged, not returned.
+var errInvalidUsernameOrPassword = fmt.Errorf("invalid username or password")
+
+// SecretGetter fetches a single Kubernetes Secret by name, letting
+// BasicAuthAuthenticator work against a SecretInterface or an informer
+// lister without depending on either directly.
+type SecretGetter interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error)
+}
+
+// BasicAuthAuthenticator authenticates HTTP Basic auth credentials against a
+// namespaced Secret named after the username, storing a bcrypt-hashed
+// password under the "password" key and a comma-separated group list under
+// the "groups" key, mirroring the Pinniped local-user-authenticator
+// webhook's Secret-backed local users.
+type BasicAuthAuthenticator struct {
+	Secrets SecretGetter
+}
+
+func (b BasicAuthAuthenticator) AuthenticateRequest(r *http.Request) (authenticationv1.UserInfo, bool, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return authenticationv1.UserInfo{}, false, nil
+	}
+
+	secret, err := b.Secrets.Get(r.Context(), username, metav1.GetOptions{})
+	if err != nil {
+		glog.Infof("middleware: basic auth: unable to find user %q: %v", username, err)
+		return authenticationv1.UserInfo{}, false, errInvalidUsernameOrPassword
+	}
+
+	if err := bcrypt.CompareHashAndPassword(secret.Data["password"], []byte(password)); err != nil {
+		glog.Infof("middleware: basic auth: wrong password for user %q", username)
+		return authenticationv1.UserInfo{}, false, errInvalidUsernameOrPassword
+	}
+
+	var groups []string
+	if g := string(secret.Data["groups"]); g != "" {
+		groups = strings.Split(g, ",")
+	}
+
+	return authenticationv1.UserInfo{Username: username, Groups: groups}, true, nil
+}
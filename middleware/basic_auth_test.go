@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeSecretGetter map[string]*corev1.Secret
+
+func (f fakeSecretGetter) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Secret, error) {
+	secret, ok := f[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %q not found", name)
+	}
+	return secret, nil
+}
+
+func TestBasicAuthAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := fakeSecretGetter{
+		"alice": &corev1.Secret{
+			Data: map[string][]byte{
+				"password": hash,
+				"groups":   []byte("broker-tenants,admins"),
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		header       bool
+		username     string
+		password     string
+		expectedOK   bool
+		expectedUser string
+		expectedErr  bool
+	}{
+		{
+			name: "no credentials presented",
+		},
+		{
+			name:        "unknown user",
+			header:      true,
+			username:    "bob",
+			password:    "hunter2",
+			expectedErr: true,
+		},
+		{
+			name:        "wrong password",
+			header:      true,
+			username:    "alice",
+			password:    "wrong",
+			expectedErr: true,
+		},
+		{
+			name:         "correct credentials",
+			header:       true,
+			username:     "alice",
+			password:     "hunter2",
+			expectedOK:   true,
+			expectedUser: "alice",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+			if tc.header {
+				req.SetBasicAuth(tc.username, tc.password)
+			}
+
+			authn := BasicAuthAuthenticator{Secrets: secrets}
+			user, ok, err := authn.AuthenticateRequest(req)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok: %v, got: %v", tc.expectedOK, ok)
+			}
+			if ok && user.Username != tc.expectedUser {
+				t.Fatalf("expected user: %v, got: %v", tc.expectedUser, user.Username)
+			}
+			if ok && (len(user.Groups) != 2 || user.Groups[0] != "broker-tenants") {
+				t.Fatalf("expected groups [broker-tenants admins], got: %v", user.Groups)
+			}
+			if tc.expectedErr && err.Error() != "invalid username or password" {
+				t.Fatalf("expected the generic error message so a caller can't enumerate usernames, got: %v", err)
+			}
+		})
+	}
+}
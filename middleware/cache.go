@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/golang/glog"
+)
+
+// AuthenticationCache stores authentication outcomes keyed by an opaque
+// cache key (the SHA-256 hash of the request's credential), letting
+// CachingAuthenticator be backed by an in-memory map, an LRU, or a shared
+// store like Redis.
+type AuthenticationCache interface {
+	Get(key string) (entry CacheEntry, found bool)
+	Set(key string, entry CacheEntry)
+}
+
+// CacheEntry is a single cached authentication outcome. Reason holds the
+// original error's message when authentication returned an error, and is
+// empty otherwise (including when OK is false without an error), so that a
+// Redis-backed implementation only needs to serialize plain strings.
+type CacheEntry struct {
+	UserInfo authenticationv1.UserInfo
+	OK       bool
+	Reason   string
+	Expires  time.Time
+}
+
+// NewInMemoryAuthenticationCache returns an AuthenticationCache backed by a
+// mutex-guarded map, sufficient for a single broker replica. Multi-replica
+// deployments should supply a shared backend instead.
+//
+// Entries are never evicted on expiry, only overwritten the next time the
+// same key is looked up and re-cached; a long-running broker seeing a
+// steady stream of distinct bearer tokens (e.g. from rotating service
+// accounts) will grow this cache for the lifetime of the process. Callers
+// with that traffic pattern should supply their own AuthenticationCache
+// backed by an LRU or a shared, size-bounded store instead.
+func NewInMemoryAuthenticationCache() AuthenticationCache {
+	glog.Warning("middleware: using the unbounded in-memory AuthenticationCache; entries are never evicted, which can grow memory without bound under high bearer-token churn")
+	return &inMemoryAuthenticationCache{entries: map[string]CacheEntry{}}
+}
+
+type inMemoryAuthenticationCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+func (c *inMemoryAuthenticationCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *inMemoryAuthenticationCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+// AuthorizationCache stores authorization outcomes keyed by an opaque cache
+// key, analogous to AuthenticationCache but for SubjectAccessReview
+// decisions.
+type AuthorizationCache interface {
+	Get(key string) (entry AuthorizationCacheEntry, found bool)
+	Set(key string, entry AuthorizationCacheEntry)
+}
+
+// AuthorizationCacheEntry is a single cached authorization outcome.
+type AuthorizationCacheEntry struct {
+	Decision Decision
+	Reason   string
+	Expires  time.Time
+}
+
+// NewInMemoryAuthorizationCache returns an AuthorizationCache backed by a
+// mutex-guarded map, sufficient for a single broker replica.
+//
+// As with NewInMemoryAuthenticationCache, entries are never evicted on
+// expiry, only overwritten on the next lookup for the same key; callers
+// serving a large, ever-changing population of users should supply their
+// own AuthorizationCache backed by an LRU or a shared, size-bounded store.
+func NewInMemoryAuthorizationCache() AuthorizationCache {
+	glog.Warning("middleware: using the unbounded in-memory AuthorizationCache; entries are never evicted, which can grow memory without bound under high user/path cardinality")
+	return &inMemoryAuthorizationCache{entries: map[string]AuthorizationCacheEntry{}}
+}
+
+type inMemoryAuthorizationCache struct {
+	mu      sync.Mutex
+	entries map[string]AuthorizationCacheEntry
+}
+
+func (c *inMemoryAuthorizationCache) Get(key string) (AuthorizationCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *inMemoryAuthorizationCache) Set(key string, entry AuthorizationCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+const (
+	defaultPositiveTTL = 2 * time.Minute
+	defaultNegativeTTL = 10 * time.Second
+)
+
+// CachingAuthenticator memoizes the result of delegating to Authenticator,
+// keyed on the SHA-256 hash of the request's Authorization header. Positive
+// results (authenticated) are kept for PositiveTTL, negative results
+// (rejected or erroring) are kept for the shorter NegativeTTL, and
+// concurrent requests for the same credential are collapsed into a single
+// call to Authenticator via singleflight. This turns the one
+// TokenReview-per-HTTP-call pattern into a handful of calls even during a
+// provisioning storm from the service-catalog controller.
+type CachingAuthenticator struct {
+	Authenticator Authenticator
+	Cache         AuthenticationCache
+
+	// PositiveTTL is how long a successful authentication is cached.
+	// Defaults to 2 minutes.
+	PositiveTTL time.Duration
+	// NegativeTTL is how long a failed authentication is cached. Defaults
+	// to 10 seconds.
+	NegativeTTL time.Duration
+
+	group singleflight.Group
+}
+
+type cachingAuthenticatorResult struct {
+	user authenticationv1.UserInfo
+	ok   bool
+	err  error
+}
+
+func (c *CachingAuthenticator) AuthenticateRequest(r *http.Request) (authenticationv1.UserInfo, bool, error) {
+	if c.Cache == nil {
+		return c.Authenticator.AuthenticateRequest(r)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return c.Authenticator.AuthenticateRequest(r)
+	}
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(header)))
+
+	if entry, found := c.Cache.Get(key); found && time.Now().Before(entry.Expires) {
+		if entry.OK {
+			return entry.UserInfo, true, nil
+		}
+		if entry.Reason == "" {
+			return authenticationv1.UserInfo{}, false, nil
+		}
+		return authenticationv1.UserInfo{}, false, errors.New(entry.Reason)
+	}
+
+	v, _, _ := c.group.Do(key, func() (interface{}, error) {
+		user, ok, authErr := c.Authenticator.AuthenticateRequest(r)
+
+		entry := CacheEntry{UserInfo: user, OK: ok, Expires: time.Now().Add(c.ttl(ok))}
+		if authErr != nil {
+			entry.Reason = authErr.Error()
+		}
+		c.Cache.Set(key, entry)
+
+		return cachingAuthenticatorResult{user: user, ok: ok, err: authErr}, nil
+	})
+
+	result := v.(cachingAuthenticatorResult)
+	return result.user, result.ok, result.err
+}
+
+func (c *CachingAuthenticator) ttl(ok bool) time.Duration {
+	if ok {
+		if c.PositiveTTL > 0 {
+			return c.PositiveTTL
+		}
+		return defaultPositiveTTL
+	}
+	if c.NegativeTTL > 0 {
+		return c.NegativeTTL
+	}
+	return defaultNegativeTTL
+}
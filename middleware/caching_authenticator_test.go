@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+type countingAuthenticator struct {
+	calls int32
+	user  authenticationv1.UserInfo
+	ok    bool
+	err   error
+}
+
+func (c *countingAuthenticator) AuthenticateRequest(*http.Request) (authenticationv1.UserInfo, bool, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.user, c.ok, c.err
+}
+
+func TestCachingAuthenticator(t *testing.T) {
+	t.Run("caches a successful result", func(t *testing.T) {
+		inner := &countingAuthenticator{user: authenticationv1.UserInfo{Username: "foo"}, ok: true}
+		c := &CachingAuthenticator{Authenticator: inner, Cache: NewInMemoryAuthenticationCache()}
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.Header.Set("Authorization", "bearer sometoken")
+
+		for i := 0; i < 3; i++ {
+			user, ok, err := c.AuthenticateRequest(req)
+			if err != nil || !ok || user.Username != "foo" {
+				t.Fatalf("unexpected result: user=%+v ok=%v err=%v", user, ok, err)
+			}
+		}
+
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authenticator to be called once, got: %d", calls)
+		}
+	})
+
+	t.Run("caches a failure with the negative TTL", func(t *testing.T) {
+		inner := &countingAuthenticator{err: fmt.Errorf("boom")}
+		c := &CachingAuthenticator{Authenticator: inner, Cache: NewInMemoryAuthenticationCache(), NegativeTTL: time.Millisecond}
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.Header.Set("Authorization", "bearer sometoken")
+
+		_, _, err := c.AuthenticateRequest(req)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		_, _, err = c.AuthenticateRequest(req)
+		if err == nil {
+			t.Fatal("expected the cached error")
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authenticator to be called once, got: %d", calls)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		if _, _, err := c.AuthenticateRequest(req); err == nil {
+			t.Fatal("expected an error after the negative TTL expired")
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 2 {
+			t.Fatalf("expected the inner authenticator to be called again after expiry, got: %d", calls)
+		}
+	})
+
+	t.Run("bypasses the cache without an Authorization header", func(t *testing.T) {
+		inner := &countingAuthenticator{ok: false}
+		c := &CachingAuthenticator{Authenticator: inner, Cache: NewInMemoryAuthenticationCache()}
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		c.AuthenticateRequest(req)
+		c.AuthenticateRequest(req)
+
+		if calls := atomic.LoadInt32(&inner.calls); calls != 2 {
+			t.Fatalf("expected no caching without a credential, got: %d calls", calls)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// CachingAuthorizer memoizes the result of delegating to Authorizer, keyed
+// on the SHA-256 hash of the user's identity and the request's method and
+// path. It follows the same positive/negative TTL and singleflight
+// de-duplication strategy as CachingAuthenticator, avoiding a fresh
+// SubjectAccessReview for every OSB call in a provisioning storm.
+type CachingAuthorizer struct {
+	Authorizer UserInfoAuthorizer
+	Cache      AuthorizationCache
+
+	// PositiveTTL is how long an Allowed decision is cached. Defaults to 2
+	// minutes.
+	PositiveTTL time.Duration
+	// NegativeTTL is how long a Denied, No-Opinion, or erroring decision
+	// is cached. Defaults to 10 seconds.
+	NegativeTTL time.Duration
+
+	group singleflight.Group
+}
+
+type cachingAuthorizerResult struct {
+	decision Decision
+	err      error
+}
+
+func (c *CachingAuthorizer) Authorize(user authenticationv1.UserInfo, r *http.Request) (Decision, error) {
+	if c.Cache == nil {
+		return c.Authorizer.Authorize(user, r)
+	}
+
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(user.Username+"|"+r.Method+"|"+r.URL.Path)))
+
+	if entry, found := c.Cache.Get(key); found && time.Now().Before(entry.Expires) {
+		if entry.Reason == "" {
+			return entry.Decision, nil
+		}
+		return entry.Decision, errors.New(entry.Reason)
+	}
+
+	v, _, _ := c.group.Do(key, func() (interface{}, error) {
+		decision, authErr := c.Authorizer.Authorize(user, r)
+
+		entry := AuthorizationCacheEntry{Decision: decision, Expires: time.Now().Add(c.ttl(decision, authErr))}
+		if authErr != nil {
+			entry.Reason = authErr.Error()
+		}
+		c.Cache.Set(key, entry)
+
+		return cachingAuthorizerResult{decision: decision, err: authErr}, nil
+	})
+
+	result := v.(cachingAuthorizerResult)
+	return result.decision, result.err
+}
+
+func (c *CachingAuthorizer) ttl(decision Decision, err error) time.Duration {
+	if decision == DecisionAllowed && err == nil {
+		if c.PositiveTTL > 0 {
+			return c.PositiveTTL
+		}
+		return defaultPositiveTTL
+	}
+	if c.NegativeTTL > 0 {
+		return c.NegativeTTL
+	}
+	return defaultNegativeTTL
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+type countingAuthorizer struct {
+	calls    int32
+	decision Decision
+	err      error
+}
+
+func (c *countingAuthorizer) Authorize(authenticationv1.UserInfo, *http.Request) (Decision, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.decision, c.err
+}
+
+func TestCachingAuthorizer(t *testing.T) {
+	t.Run("caches an allowed decision", func(t *testing.T) {
+		inner := &countingAuthorizer{decision: DecisionAllowed}
+		c := &CachingAuthorizer{Authorizer: inner, Cache: NewInMemoryAuthorizationCache()}
+
+		req := httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-1", nil)
+		user := authenticationv1.UserInfo{Username: "foo"}
+
+		for i := 0; i < 3; i++ {
+			decision, err := c.Authorize(user, req)
+			if err != nil || decision != DecisionAllowed {
+				t.Fatalf("unexpected result: decision=%v err=%v", decision, err)
+			}
+		}
+
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authorizer to be called once, got: %d", calls)
+		}
+	})
+
+	t.Run("caches a denied decision with the negative TTL", func(t *testing.T) {
+		inner := &countingAuthorizer{decision: DecisionDeny}
+		c := &CachingAuthorizer{Authorizer: inner, Cache: NewInMemoryAuthorizationCache(), NegativeTTL: time.Millisecond}
+
+		req := httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-1", nil)
+		user := authenticationv1.UserInfo{Username: "foo"}
+
+		decision, err := c.Authorize(user, req)
+		if err != nil || decision != DecisionDeny {
+			t.Fatalf("unexpected result: decision=%v err=%v", decision, err)
+		}
+		decision, err = c.Authorize(user, req)
+		if err != nil || decision != DecisionDeny {
+			t.Fatalf("unexpected cached result: decision=%v err=%v", decision, err)
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authorizer to be called once, got: %d", calls)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		if _, err := c.Authorize(user, req); err != nil {
+			t.Fatalf("unexpected error after the negative TTL expired: %v", err)
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 2 {
+			t.Fatalf("expected the inner authorizer to be called again after expiry, got: %d", calls)
+		}
+	})
+
+	t.Run("caches a no-opinion decision with the negative TTL", func(t *testing.T) {
+		inner := &countingAuthorizer{decision: DecisionNoOpinion}
+		c := &CachingAuthorizer{Authorizer: inner, Cache: NewInMemoryAuthorizationCache(), NegativeTTL: time.Hour}
+
+		req := httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-1", nil)
+		user := authenticationv1.UserInfo{Username: "foo"}
+
+		for i := 0; i < 3; i++ {
+			decision, err := c.Authorize(user, req)
+			if err != nil || decision != DecisionNoOpinion {
+				t.Fatalf("unexpected result: decision=%v err=%v", decision, err)
+			}
+		}
+
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authorizer to be called once, got: %d", calls)
+		}
+	})
+
+	t.Run("caches an error with the negative TTL", func(t *testing.T) {
+		inner := &countingAuthorizer{decision: DecisionDeny, err: fmt.Errorf("unable to complete SAR")}
+		c := &CachingAuthorizer{Authorizer: inner, Cache: NewInMemoryAuthorizationCache(), NegativeTTL: time.Millisecond}
+
+		req := httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-1", nil)
+		user := authenticationv1.UserInfo{Username: "foo"}
+
+		_, err := c.Authorize(user, req)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		_, err = c.Authorize(user, req)
+		if err == nil {
+			t.Fatal("expected the cached error")
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 1 {
+			t.Fatalf("expected the inner authorizer to be called once, got: %d", calls)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		if _, err := c.Authorize(user, req); err == nil {
+			t.Fatal("expected an error after the negative TTL expired")
+		}
+		if calls := atomic.LoadInt32(&inner.calls); calls != 2 {
+			t.Fatalf("expected the inner authorizer to be called again after expiry, got: %d", calls)
+		}
+	})
+
+	t.Run("bypasses the cache when unset", func(t *testing.T) {
+		inner := &countingAuthorizer{decision: DecisionAllowed}
+		c := &CachingAuthorizer{Authorizer: inner}
+
+		req := httptest.NewRequest(http.MethodGet, "/v2/service_instances/instance-1", nil)
+		user := authenticationv1.UserInfo{Username: "foo"}
+
+		c.Authorize(user, req)
+		c.Authorize(user, req)
+
+		if calls := atomic.LoadInt32(&inner.calls); calls != 2 {
+			t.Fatalf("expected no caching without a Cache, got: %d calls", calls)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// contextKey is an unexported type to avoid collisions with context keys
+// defined in other packages.
+type contextKey int
+
+const (
+	// userInfoKey is the context key under which AuthenticationMiddleware
+	// stores the authenticated caller's UserInfo.
+	userInfoKey contextKey = iota
+	// requestIDKey is the context key under which RequestIDMiddleware
+	// stores the request's correlation ID.
+	requestIDKey
+)
+
+// contextWithUserInfo returns a copy of ctx carrying the authenticated
+// user's identity, retrievable with UserInfoFromContext.
+func contextWithUserInfo(ctx context.Context, user authenticationv1.UserInfo) context.Context {
+	return context.WithValue(ctx, userInfoKey, user)
+}
+
+// UserInfoFromContext returns the UserInfo that AuthenticationMiddleware
+// authenticated the request as, and ok=true if one is present. Broker
+// Handler funcs (e.g. those implementing pmorie/osb-broker-lib's Broker
+// interface) can call this on the context passed to them to log audit
+// trails, enforce per-user quotas, or stamp originating-identity on
+// downstream Kubernetes objects.
+func UserInfoFromContext(ctx context.Context) (authenticationv1.UserInfo, bool) {
+	user, ok := ctx.Value(userInfoKey).(authenticationv1.UserInfo)
+	return user, ok
+}
+
+// contextWithRequestID returns a copy of ctx carrying id, retrievable with
+// RequestIDFromContext.
+func contextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the correlation ID RequestIDMiddleware
+// stamped onto the request, and ok=true if one is present.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
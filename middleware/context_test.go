@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestAuthenticationMiddlewarePropagatesUserInfo(t *testing.T) {
+	am := AuthenticationMiddleware{
+		Authenticators: []Authenticator{
+			TokenReviewAuthenticator{
+				TokenReview: fakeTokenReview{&authenticationv1.TokenReview{
+					Spec:   authenticationv1.TokenReviewSpec{Token: "newsimpletoken"},
+					Status: authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "foo"}},
+				}},
+			},
+		},
+	}
+
+	var gotUser authenticationv1.UserInfo
+	var gotOK bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotOK = UserInfoFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+	req.Header.Add("Authorization", "bearer newsimpletoken")
+	am.Middleware(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !gotOK {
+		t.Fatal("expected UserInfo to be present in the handler's context")
+	}
+	if gotUser.Username != "foo" {
+		t.Fatalf("expected username foo, got: %v", gotUser.Username)
+	}
+}
+
+func TestUserInfoFromContextMissing(t *testing.T) {
+	_, ok := UserInfoFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	if ok {
+		t.Fatal("expected no UserInfo on a bare request context")
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// osbError is the JSON error body returned to Open Service Broker API
+// clients, matching the error response schema defined by the OSB spec.
+type osbError struct {
+	Error       string `json:"error,omitempty"`
+	Description string `json:"description"`
+}
+
+// writeError writes an OSB-formatted JSON error response with the given
+// HTTP status code and description.
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(osbError{Description: description})
+}
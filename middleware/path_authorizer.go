@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// PathAuthorizer unconditionally allows requests for a configured set of
+// paths (e.g. /healthz, /metrics) and has no opinion on anything else. It is
+// intended to be the first link in a UnionAuthorizer chain so that operators
+// can expose those paths without having to teach every other authorizer
+// about them. PathAuthorizer only grants authorization, though — a request
+// still has to authenticate first. To expose a path to callers that present
+// no credentials at all, pair PathAuthorizer with an AnonymousAuthenticator
+// at the end of AuthenticationMiddleware's Authenticators list, so
+// credential-less requests reach the authorizer instead of being rejected
+// during authentication.
+type PathAuthorizer struct {
+	Paths []string
+}
+
+func (p PathAuthorizer) Authorize(user authenticationv1.UserInfo, r *http.Request) (Decision, error) {
+	for _, path := range p.Paths {
+		if r.URL.Path == path {
+			return DecisionAllowed, nil
+		}
+	}
+	return DecisionNoOpinion, nil
+}
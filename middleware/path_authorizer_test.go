@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestPathAuthorizer(t *testing.T) {
+	testCases := []struct {
+		name             string
+		paths            []string
+		reqURL           string
+		expectedDecision Decision
+	}{
+		{
+			name:             "allow-listed path",
+			paths:            []string{"/healthz", "/metrics"},
+			reqURL:           "/healthz",
+			expectedDecision: DecisionAllowed,
+		},
+		{
+			name:             "path not on the allow-list",
+			paths:            []string{"/healthz", "/metrics"},
+			reqURL:           "/v2/catalog",
+			expectedDecision: DecisionNoOpinion,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tc.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := PathAuthorizer{Paths: tc.paths}
+			dec, err := p.Authorize(authenticationv1.UserInfo{}, req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dec != tc.expectedDecision {
+				t.Fatalf("expected: %v decision got: %v", tc.expectedDecision, dec)
+			}
+		})
+	}
+}
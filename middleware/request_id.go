@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// validRequestID matches the conservative charset we accept from a
+// caller-supplied X-Request-ID: if a request ID could contain any
+// character it could also contain newlines, which would let a caller
+// forge extra lines in a log-based AuditSink such as GlogAuditSink. Any
+// header that doesn't match is discarded in favor of a generated ID.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// requestIDHeader is the header an OSB request's correlation ID is read
+// from and stamped back onto, letting the service-catalog controller,
+// broker, and kube-apiserver audit logs for a single call be joined on one
+// value.
+const requestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware stamps a request ID into the response headers and the
+// request's context, generating one if the caller didn't already supply
+// one. It should run before AuthenticationMiddleware so that
+// AuditSink events can include the correlation ID.
+type RequestIDMiddleware struct{}
+
+func (RequestIDMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(contextWithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
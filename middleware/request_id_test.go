@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Run("generates a request ID when none is supplied", func(t *testing.T) {
+		var gotID string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotID, _ = RequestIDFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		w := httptest.NewRecorder()
+		RequestIDMiddleware{}.Middleware(next).ServeHTTP(w, req)
+
+		if gotID == "" {
+			t.Fatal("expected a generated request ID in the context")
+		}
+		if w.Header().Get(requestIDHeader) != gotID {
+			t.Fatalf("expected response header %q to match context ID %q, got: %q", requestIDHeader, gotID, w.Header().Get(requestIDHeader))
+		}
+	})
+
+	t.Run("preserves a caller-supplied request ID", func(t *testing.T) {
+		var gotID string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotID, _ = RequestIDFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.Header.Set(requestIDHeader, "caller-supplied-id")
+		w := httptest.NewRecorder()
+		RequestIDMiddleware{}.Middleware(next).ServeHTTP(w, req)
+
+		if gotID != "caller-supplied-id" {
+			t.Fatalf("expected the caller-supplied request ID to be preserved, got: %q", gotID)
+		}
+		if w.Header().Get(requestIDHeader) != "caller-supplied-id" {
+			t.Fatalf("expected response header to echo the caller-supplied ID, got: %q", w.Header().Get(requestIDHeader))
+		}
+	})
+
+	t.Run("discards a caller-supplied request ID containing control characters", func(t *testing.T) {
+		var gotID string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotID, _ = RequestIDFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.Header.Set(requestIDHeader, "abc\naudit authz decision=allowed")
+		w := httptest.NewRecorder()
+		RequestIDMiddleware{}.Middleware(next).ServeHTTP(w, req)
+
+		if gotID == "" || !validRequestID.MatchString(gotID) {
+			t.Fatalf("expected a freshly generated, well-formed request ID, got: %q", gotID)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+func TestOSBResourceAttributesResolver(t *testing.T) {
+	testCases := []struct {
+		name          string
+		reqURL        string
+		reqMethod     string
+		expectedAttrs *authorizationv1.ResourceAttributes
+		expectedOK    bool
+	}{
+		{
+			name:       "catalog is not resource scoped",
+			reqURL:     "/v2/catalog",
+			reqMethod:  http.MethodGet,
+			expectedOK: false,
+		},
+		{
+			name:      "provision an instance",
+			reqURL:    "/v2/service_instances/instance-1",
+			reqMethod: http.MethodPut,
+			expectedAttrs: &authorizationv1.ResourceAttributes{
+				Verb:     "create",
+				Group:    "servicecatalog.k8s.io",
+				Resource: "serviceinstances",
+				Name:     "instance-1",
+			},
+			expectedOK: true,
+		},
+		{
+			name:      "deprovision an instance",
+			reqURL:    "/v2/service_instances/instance-1",
+			reqMethod: http.MethodDelete,
+			expectedAttrs: &authorizationv1.ResourceAttributes{
+				Verb:     "delete",
+				Group:    "servicecatalog.k8s.io",
+				Resource: "serviceinstances",
+				Name:     "instance-1",
+			},
+			expectedOK: true,
+		},
+		{
+			name:      "fetch an instance's last operation",
+			reqURL:    "/v2/service_instances/instance-1/last_operation",
+			reqMethod: http.MethodGet,
+			expectedAttrs: &authorizationv1.ResourceAttributes{
+				Verb:     "get",
+				Group:    "servicecatalog.k8s.io",
+				Resource: "serviceinstances",
+				Name:     "instance-1",
+			},
+			expectedOK: true,
+		},
+		{
+			name:      "bind to an instance",
+			reqURL:    "/v2/service_instances/instance-1/service_bindings/binding-1",
+			reqMethod: http.MethodPut,
+			expectedAttrs: &authorizationv1.ResourceAttributes{
+				Verb:     "create",
+				Group:    "servicecatalog.k8s.io",
+				Resource: "servicebindings",
+				Name:     "binding-1",
+			},
+			expectedOK: true,
+		},
+		{
+			name:      "unbind from an instance",
+			reqURL:    "/v2/service_instances/instance-1/service_bindings/binding-1",
+			reqMethod: http.MethodDelete,
+			expectedAttrs: &authorizationv1.ResourceAttributes{
+				Verb:     "delete",
+				Group:    "servicecatalog.k8s.io",
+				Resource: "servicebindings",
+				Name:     "binding-1",
+			},
+			expectedOK: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.reqMethod, tc.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			attrs, ok := (OSBResourceAttributesResolver{}).ResolveResourceAttributes(req)
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok: %v, got: %v", tc.expectedOK, ok)
+			}
+			if !reflect.DeepEqual(attrs, tc.expectedAttrs) {
+				t.Fatalf("expected attrs: %+v, got: %+v", tc.expectedAttrs, attrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+
+	authnv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+// TokenReviewAuthenticator authenticates the bearer token carried in a
+// request's Authorization header against the Kubernetes TokenReview API.
+type TokenReviewAuthenticator struct {
+	TokenReview authnv1client.TokenReviewInterface
+}
+
+func (t TokenReviewAuthenticator) AuthenticateRequest(r *http.Request) (authenticationv1.UserInfo, bool, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return authenticationv1.UserInfo{}, false, nil
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return authenticationv1.UserInfo{}, false, fmt.Errorf("invalid authentication")
+	}
+
+	tr, err := t.TokenReview.Create(r.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: parts[1]},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return authenticationv1.UserInfo{}, false, fmt.Errorf("unable to authenticate token")
+	}
+	if !tr.Status.Authenticated {
+		return authenticationv1.UserInfo{}, false, fmt.Errorf("user was not authenticated")
+	}
+
+	return withTokenReviewUID(tr.Status.User, tr), true, nil
+}
+
+// withTokenReviewUID stamps the TokenReview's own UID onto user.Extra so
+// that audit events can correlate a broker's authentication decision back
+// to the exact TokenReview the apiserver recorded in its audit log.
+func withTokenReviewUID(user authenticationv1.UserInfo, tr *authenticationv1.TokenReview) authenticationv1.UserInfo {
+	if tr.UID == "" {
+		return user
+	}
+
+	extra := make(map[string]authenticationv1.ExtraValue, len(user.Extra)+1)
+	for k, v := range user.Extra {
+		extra[k] = v
+	}
+	extra[tokenReviewUIDExtraKey] = authenticationv1.ExtraValue{string(tr.UID)}
+	user.Extra = extra
+	return user
+}
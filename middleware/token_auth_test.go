@@ -2,172 +2,100 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
-	authenticationapi "k8s.io/api/authentication/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
-	"k8s.io/client-go/kubernetes/typed/authentication/v1"
 )
 
 type fakeTokenReview struct {
-	TokenReview *authenticationapi.TokenReview
+	TokenReview *authenticationv1.TokenReview
 }
 
-func (ftr fakeTokenReview) Create(ctx context.Context, tr *authenticationapi.TokenReview, opts metav1.CreateOptions) (*authenticationapi.TokenReview, error) {
+func (ftr fakeTokenReview) Create(ctx context.Context, tr *authenticationv1.TokenReview, opts metav1.CreateOptions) (*authenticationv1.TokenReview, error) {
 	if tr.Spec.Token != ftr.TokenReview.Spec.Token {
 		return nil, fmt.Errorf("token was not the same")
 	}
 	return ftr.TokenReview, nil
 }
 
-type fakeAuthorizer struct {
-	Decision Decision
-	Err      error
-}
-
-func (f fakeAuthorizer) Authorize(u authenticationapi.UserInfo, r *http.Request) (Decision, error) {
-	return f.Decision, f.Err
-}
-
-func TestTokenReviewMiddleware(t *testing.T) {
+func TestTokenReviewAuthenticator(t *testing.T) {
 	cases := []struct {
-		name         string
-		url          string
-		tokenReview  v1.TokenReviewInterface
-		authorizer   UserInfoAuthorizer
+		name        string
+		tokenReview interface {
+			Create(context.Context, *authenticationv1.TokenReview, metav1.CreateOptions) (*authenticationv1.TokenReview, error)
+		}
 		header       string
-		responseCode int
+		expectedOK   bool
+		expectedUser authenticationv1.UserInfo
 		errorMessage string
 	}{
 		{
-			name:         "no auth string",
-			tokenReview:  fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
-			header:       "",
-			responseCode: http.StatusUnauthorized,
-			errorMessage: "unable to find authentication token",
+			name:        "no auth string",
+			tokenReview: fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
 		},
 		{
 			name:         "only bearer in auth string",
 			tokenReview:  fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
 			header:       "bearer",
-			responseCode: http.StatusUnauthorized,
 			errorMessage: "invalid authentication",
 		},
 		{
 			name:         "no bearer in auth string",
 			tokenReview:  fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
 			header:       "faker newsimpletoken",
-			responseCode: http.StatusUnauthorized,
 			errorMessage: "invalid authentication",
 		},
 		{
 			name:         "unauthenticated user",
 			tokenReview:  fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
 			header:       "bearer newsimpletoken",
-			responseCode: http.StatusUnauthorized,
 			errorMessage: "user was not authenticated",
 		},
-		{
-			name:         "unauthenticated user + healthz",
-			url:          "/healthz",
-			tokenReview:  fake.NewSimpleClientset().AuthenticationV1().TokenReviews(),
-			responseCode: http.StatusOK,
-		},
 		{
 			name:         "token review failure",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
+			tokenReview:  fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true}}},
 			header:       "bearer anothertoken",
-			responseCode: http.StatusUnauthorized,
 			errorMessage: "unable to authenticate token",
 		},
 		{
 			name:         "authenticated user",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
-			header:       "bearer newsimpletoken",
-			responseCode: http.StatusOK,
-			errorMessage: "",
-		},
-		{
-			name:         "authenticated & authorized user",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
-			authorizer:   fakeAuthorizer{Decision: DecisionAllowed, Err: nil},
-			header:       "bearer newsimpletoken",
-			responseCode: http.StatusOK,
-			errorMessage: "",
-		},
-		{
-			name:         "authenticated & denied user",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
-			authorizer:   fakeAuthorizer{Decision: DecisionDeny, Err: nil},
+			tokenReview:  fakeTokenReview{&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "foo"}}}},
 			header:       "bearer newsimpletoken",
-			responseCode: http.StatusUnauthorized,
-			errorMessage: "unable to authorize user",
-		},
-		{
-			name:         "authenticated & no opinion on user",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
-			authorizer:   fakeAuthorizer{Decision: DecisionNoOpinion, Err: nil},
-			header:       "bearer newsimpletoken",
-			responseCode: http.StatusUnauthorized,
-			errorMessage: "unable to authorize user",
-		},
-		{
-			name:         "authenticated & error authorizing user",
-			tokenReview:  fakeTokenReview{&authenticationapi.TokenReview{Spec: authenticationapi.TokenReviewSpec{Token: "newsimpletoken"}, Status: authenticationapi.TokenReviewStatus{Authenticated: true}}},
-			authorizer:   fakeAuthorizer{Decision: DecisionDeny, Err: fmt.Errorf("unable to complete SAR")},
-			header:       "bearer newsimpletoken",
-			responseCode: http.StatusUnauthorized,
-			errorMessage: "unable to authorize user",
+			expectedOK:   true,
+			expectedUser: authenticationv1.UserInfo{Username: "foo"},
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			trm := TokenReviewMiddleware{
-				TokenReview: tc.tokenReview,
-				Authorizer:  tc.authorizer,
-			}
-
-			url := "http://example.com/foo"
-			if tc.url != "" {
-				url = tc.url
-			}
-			req := httptest.NewRequest("GET", url, nil)
-
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
 			if tc.header != "" {
 				req.Header.Add("Authorization", tc.header)
 			}
 
-			w := httptest.NewRecorder()
-			trm.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				return
-			})).ServeHTTP(w, req)
-			resp := w.Result()
-			if resp.StatusCode == http.StatusOK && tc.responseCode == resp.StatusCode {
-				return
+			authn := TokenReviewAuthenticator{TokenReview: tc.tokenReview}
+			user, ok, err := authn.AuthenticateRequest(req)
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok: %v, got: %v", tc.expectedOK, ok)
 			}
-			if resp.StatusCode != tc.responseCode {
-				t.Fatalf("invalid response code expected %v, got: %v", tc.responseCode, w.Code)
+			if ok && !reflect.DeepEqual(user, tc.expectedUser) {
+				t.Fatalf("expected user: %+v, got: %+v", tc.expectedUser, user)
 			}
-			if resp.Header.Get("Content-Type") != "application/json" {
-				t.Fatalf("invalid content type expected %v, got: %v", "application/json", w.Header().Get("Content-Type"))
-			}
-			defer resp.Body.Close()
-			e := osbError{}
-			err := json.NewDecoder(resp.Body).Decode(&e)
-			if err != nil {
-				t.Fatalf("invalid json data in response body: %v", err)
+			if tc.errorMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
 			}
-			if e.Description != tc.errorMessage {
-				t.Fatalf("invalid error description expected %v, got: %v", tc.errorMessage, e.Description)
+			if err == nil || err.Error() != tc.errorMessage {
+				t.Fatalf("expected error: %q, got: %v", tc.errorMessage, err)
 			}
-
 		})
 	}
 }
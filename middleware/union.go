@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// UnionAuthorizer fans a request out to a list of UserInfoAuthorizers in
+// order, returning the first decision that is not DecisionNoOpinion. This
+// mirrors Kubernetes' own union authorizer semantics: an explicit Allow or
+// Deny short-circuits the chain, while No-Opinion falls through to the next
+// authorizer — and so does an authorizer that errors, the same way
+// UnionAuthenticator skips past an erroring Authenticator, so a transient
+// failure in one authorizer (e.g. a SubjectAccessReview API error) doesn't
+// deny a request another authorizer in the chain would have allowed.
+type UnionAuthorizer []UserInfoAuthorizer
+
+func (u UnionAuthorizer) Authorize(user authenticationv1.UserInfo, r *http.Request) (Decision, error) {
+	var errs []error
+	for _, authorizer := range u {
+		decision, err := authorizer.Authorize(user, r)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch decision {
+		case DecisionAllowed, DecisionDeny:
+			return decision, nil
+		case DecisionNoOpinion:
+			continue
+		}
+	}
+	return DecisionNoOpinion, aggregateErrors(errs)
+}
+
+// UnionAuthenticator fans a request out to a list of Authenticators in
+// order, returning the first one that has an opinion on the request (ok is
+// true). This allows a broker to accept more than one form of credential,
+// e.g. bearer tokens from the service-catalog controller and client
+// certificates presented over mTLS.
+type UnionAuthenticator []Authenticator
+
+func (u UnionAuthenticator) AuthenticateRequest(r *http.Request) (authenticationv1.UserInfo, bool, error) {
+	var errs []error
+	for _, authenticator := range u {
+		user, ok, err := authenticator.AuthenticateRequest(r)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			return user, true, nil
+		}
+	}
+	return authenticationv1.UserInfo{}, false, aggregateErrors(errs)
+}
+
+// aggregateErrors joins a list of errors into a single error, or returns nil
+// if the list is empty.
+func aggregateErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
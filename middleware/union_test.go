@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+type fakeUserInfoAuthorizer struct {
+	decision Decision
+	err      error
+}
+
+func (f fakeUserInfoAuthorizer) Authorize(authenticationv1.UserInfo, *http.Request) (Decision, error) {
+	return f.decision, f.err
+}
+
+func TestUnionAuthorizer(t *testing.T) {
+	testCases := []struct {
+		name             string
+		authorizers      UnionAuthorizer
+		expectedDecision Decision
+		shouldError      bool
+	}{
+		{
+			name: "first allow wins",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionAllowed},
+				fakeUserInfoAuthorizer{decision: DecisionDeny},
+			},
+			expectedDecision: DecisionAllowed,
+		},
+		{
+			name: "deny short-circuits remaining authorizers",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionDeny},
+				fakeUserInfoAuthorizer{decision: DecisionAllowed},
+			},
+			expectedDecision: DecisionDeny,
+		},
+		{
+			name: "no opinion falls through",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionNoOpinion},
+				fakeUserInfoAuthorizer{decision: DecisionAllowed},
+			},
+			expectedDecision: DecisionAllowed,
+		},
+		{
+			name: "all no opinion",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionNoOpinion},
+				fakeUserInfoAuthorizer{decision: DecisionNoOpinion},
+			},
+			expectedDecision: DecisionNoOpinion,
+		},
+		{
+			name: "an erroring authorizer is skipped in favor of a later authorizer",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionDeny, err: fmt.Errorf("SAR failed")},
+				fakeUserInfoAuthorizer{decision: DecisionAllowed},
+			},
+			expectedDecision: DecisionAllowed,
+		},
+		{
+			name: "errors are aggregated when nothing decides",
+			authorizers: UnionAuthorizer{
+				fakeUserInfoAuthorizer{decision: DecisionDeny, err: fmt.Errorf("SAR failed")},
+			},
+			expectedDecision: DecisionNoOpinion,
+			shouldError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/testing", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := tc.authorizers.Authorize(authenticationv1.UserInfo{}, req)
+			if (err != nil) != tc.shouldError {
+				t.Fatalf("unexpected error state, shouldError: %v, got: %v", tc.shouldError, err)
+			}
+			if dec != tc.expectedDecision {
+				t.Fatalf("expected: %v decision got: %v", tc.expectedDecision, dec)
+			}
+		})
+	}
+}
+
+type fakeAuthenticator struct {
+	user authenticationv1.UserInfo
+	ok   bool
+	err  error
+}
+
+func (f fakeAuthenticator) AuthenticateRequest(*http.Request) (authenticationv1.UserInfo, bool, error) {
+	return f.user, f.ok, f.err
+}
+
+func TestUnionAuthenticator(t *testing.T) {
+	testCases := []struct {
+		name         string
+		authn        UnionAuthenticator
+		expectedUser authenticationv1.UserInfo
+		expectedOK   bool
+		shouldError  bool
+	}{
+		{
+			name: "first authenticator with an opinion wins",
+			authn: UnionAuthenticator{
+				fakeAuthenticator{ok: false},
+				fakeAuthenticator{user: authenticationv1.UserInfo{Username: "foo"}, ok: true},
+			},
+			expectedUser: authenticationv1.UserInfo{Username: "foo"},
+			expectedOK:   true,
+		},
+		{
+			name: "errors are skipped in favor of a later authenticator",
+			authn: UnionAuthenticator{
+				fakeAuthenticator{err: fmt.Errorf("bad cert")},
+				fakeAuthenticator{user: authenticationv1.UserInfo{Username: "foo"}, ok: true},
+			},
+			expectedUser: authenticationv1.UserInfo{Username: "foo"},
+			expectedOK:   true,
+		},
+		{
+			name: "no authenticator has an opinion",
+			authn: UnionAuthenticator{
+				fakeAuthenticator{ok: false},
+				fakeAuthenticator{ok: false},
+			},
+			expectedOK: false,
+		},
+		{
+			name: "errors are aggregated when nothing authenticates",
+			authn: UnionAuthenticator{
+				fakeAuthenticator{err: fmt.Errorf("bad cert")},
+			},
+			expectedOK:  false,
+			shouldError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/testing", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, ok, err := tc.authn.AuthenticateRequest(req)
+			if (err != nil) != tc.shouldError {
+				t.Fatalf("unexpected error state, shouldError: %v, got: %v", tc.shouldError, err)
+			}
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok: %v, got: %v", tc.expectedOK, ok)
+			}
+			if ok && !reflect.DeepEqual(user, tc.expectedUser) {
+				t.Fatalf("expected user: %+v, got: %+v", tc.expectedUser, user)
+			}
+		})
+	}
+}
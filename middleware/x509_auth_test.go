@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func selfSignedCert(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestX509ClientCertAuthenticator(t *testing.T) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:   "alice",
+			Organization: []string{"broker-tenants"},
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert := selfSignedCert(t, template)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	t.Run("no client certificate presented", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		authn := X509ClientCertAuthenticator{CAs: pool}
+		_, ok, err := authn.AuthenticateRequest(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected no opinion without a client certificate")
+		}
+	})
+
+	t.Run("valid client certificate", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		authn := X509ClientCertAuthenticator{CAs: pool}
+		user, ok, err := authn.AuthenticateRequest(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatal("expected authentication to succeed")
+		}
+		if user.Username != "alice" {
+			t.Fatalf("expected username alice, got: %v", user.Username)
+		}
+		if len(user.Groups) != 1 || user.Groups[0] != "broker-tenants" {
+			t.Fatalf("expected groups [broker-tenants], got: %v", user.Groups)
+		}
+	})
+
+	t.Run("certificate not signed by a trusted CA", func(t *testing.T) {
+		other := selfSignedCert(t, template)
+		req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}}
+		authn := X509ClientCertAuthenticator{CAs: x509.NewCertPool()}
+		_, ok, err := authn.AuthenticateRequest(req)
+		if err == nil {
+			t.Fatal("expected an error for an untrusted certificate")
+		}
+		if ok {
+			t.Fatal("expected authentication to fail")
+		}
+	})
+}